@@ -4,40 +4,92 @@
 package description
 
 import (
+	"net/netip"
+
 	"github.com/juju/errors"
 	"github.com/juju/schema"
+
+	"github.com/juju/description/internal/schemamigrate"
+)
+
+// AddressType is a true alias (not a distinct type) for string, so that
+// existing callers passing plain strings for Type/Scope/Origin keep
+// compiling unchanged, while the constants below give call sites a named,
+// documented vocabulary to use instead. AddressType describes the kind of
+// value held by an address - for example whether it is an IPv4 address, an
+// IPv6 address, or a hostname.
+type AddressType = string
+
+const (
+	AddressTypeIPv4     AddressType = "ipv4"
+	AddressTypeIPv6     AddressType = "ipv6"
+	AddressTypeHostname AddressType = "hostname"
+)
+
+// AddressScope is a true alias for string; see AddressType. It describes
+// the network visibility of an address.
+type AddressScope = string
+
+const (
+	ScopePublic       AddressScope = "public"
+	ScopeCloudLocal   AddressScope = "local-cloud"
+	ScopeMachineLocal AddressScope = "local-machine"
+	ScopeLinkLocal    AddressScope = "link-local"
+)
+
+// AddressOrigin is a true alias for string; see AddressType. It describes
+// who determined an address - the provider, or the machine itself.
+type AddressOrigin = string
+
+const (
+	OriginProvider AddressOrigin = "provider"
+	OriginMachine  AddressOrigin = "machine"
 )
 
 // Address represents an IP Address of some form.
 type Address interface {
 	Value() string
-	Type() string
-	Scope() string
-	Origin() string
+	Type() AddressType
+	Scope() AddressScope
+	Origin() AddressOrigin
 	SpaceID() string
 	CIDR() string
+	UUID() string
+
+	// Validate checks that the address fields are internally consistent,
+	// for example that Value parses according to Type and, when CIDR is
+	// set, that it actually contains Value.
+	Validate() error
 }
 
 // AddressArgs is an argument struct used to create a new internal address
 // type that supports the Address interface.
 type AddressArgs struct {
 	Value   string
-	Type    string
-	Scope   string
-	Origin  string
+	Type    AddressType
+	Scope   AddressScope
+	Origin  AddressOrigin
 	SpaceID string
 	CIDR    string
+	UUID    string
+}
+
+// Validate checks that the args are internally consistent, in the same way
+// that Address.Validate checks the resulting address.
+func (args AddressArgs) Validate() error {
+	return validateAddress(args.Value, args.Type, args.Scope, args.Origin, args.CIDR)
 }
 
 func newAddress(args AddressArgs) *address {
 	return &address{
-		Version:  3,
+		Version:  4,
 		Value_:   args.Value,
 		Type_:    args.Type,
 		Scope_:   args.Scope,
 		Origin_:  args.Origin,
 		SpaceID_: args.SpaceID,
 		CIDR_:    args.CIDR,
+		UUID_:    args.UUID,
 	}
 }
 
@@ -51,6 +103,7 @@ type address struct {
 	Origin_  string `yaml:"origin,omitempty"`
 	SpaceID_ string `yaml:"spaceid,omitempty"`
 	CIDR_    string `yaml:"cidr,omitempty"`
+	UUID_    string `yaml:"uuid,omitempty"`
 }
 
 // Value implements Address.
@@ -59,17 +112,17 @@ func (a *address) Value() string {
 }
 
 // Type implements Address.
-func (a *address) Type() string {
+func (a *address) Type() AddressType {
 	return a.Type_
 }
 
 // Scope implements Address.
-func (a *address) Scope() string {
+func (a *address) Scope() AddressScope {
 	return a.Scope_
 }
 
 // Origin implements Address.
-func (a *address) Origin() string {
+func (a *address) Origin() AddressOrigin {
 	return a.Origin_
 }
 
@@ -83,6 +136,58 @@ func (a *address) CIDR() string {
 	return a.CIDR_
 }
 
+// UUID implements Address.
+func (a *address) UUID() string {
+	return a.UUID_
+}
+
+// Validate implements Address.
+func (a *address) Validate() error {
+	return validateAddress(a.Value_, a.Type_, a.Scope_, a.Origin_, a.CIDR_)
+}
+
+// validateAddress holds the structural checks shared by Address.Validate and
+// AddressArgs.Validate: the Type/Scope/Origin enums are known, Value parses
+// according to Type, and, when set, CIDR actually contains Value.
+func validateAddress(value, addrType, scope, origin, cidr string) error {
+	switch AddressType(addrType) {
+	case AddressTypeIPv4, AddressTypeIPv6, AddressTypeHostname:
+	default:
+		return errors.NotValidf("address type %q", addrType)
+	}
+	switch AddressScope(scope) {
+	case "", ScopePublic, ScopeCloudLocal, ScopeMachineLocal, ScopeLinkLocal:
+	default:
+		return errors.NotValidf("address scope %q", scope)
+	}
+	switch AddressOrigin(origin) {
+	case "", OriginProvider, OriginMachine:
+	default:
+		return errors.NotValidf("address origin %q", origin)
+	}
+
+	var addr netip.Addr
+	switch AddressType(addrType) {
+	case AddressTypeIPv4, AddressTypeIPv6:
+		parsed, err := netip.ParseAddr(value)
+		if err != nil {
+			return errors.NotValidf("address value %q for type %q", value, addrType)
+		}
+		addr = parsed
+	}
+
+	if cidr != "" && addr.IsValid() {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return errors.NotValidf("CIDR %q", cidr)
+		}
+		if !prefix.Contains(addr) {
+			return errors.NotValidf("address %q not in CIDR %q", value, cidr)
+		}
+	}
+	return nil
+}
+
 func importAddresses(sourceList []interface{}) ([]*address, error) {
 	var result []*address
 	for i, value := range sourceList {
@@ -107,124 +212,281 @@ func importAddress(source map[string]interface{}) (*address, error) {
 		return nil, errors.Annotate(err, "address version schema check failed")
 	}
 
-	importFunc, ok := addressDeserializationFuncs[version]
-	if !ok {
-		return nil, errors.NotValidf("version %d", version)
+	addr, err := addressMigrator.Import(source, version)
+	if err != nil {
+		return nil, errors.Trace(err)
 	}
-
-	return importFunc(source)
+	return addr, nil
 }
 
-type addressDeserializationFunc func(map[string]interface{}) (*address, error)
+// addressMigrator dispatches importAddress across every version of the
+// address document that has existed. Each version's fields are declared as
+// an extension of its predecessor via Builder.Extends, making the "VN
+// extends V(N-1)" convention explicit instead of leaving it implicit in a
+// chain of addressVNFields helpers.
+var addressMigrator = newAddressMigrator()
+
+func newAddressMigrator() *schemamigrate.Migrator[*address] {
+	builder := schemamigrate.NewBuilder[*address]()
+
+	builder.Register(schemamigrate.Registration[*address]{
+		Version: 1,
+		Fields: schema.Fields{
+			"value":  schema.String(),
+			"type":   schema.String(),
+			"scope":  schema.String(),
+			"origin": schema.String(),
+		},
+		// Some values don't have to be there.
+		Defaults: schema.Defaults{
+			"scope":  "",
+			"origin": "",
+		},
+		Build: func(valid map[string]interface{}) (*address, error) {
+			return &address{
+				Version: 1,
+				Value_:  valid["value"].(string),
+				Type_:   valid["type"].(string),
+				Scope_:  valid["scope"].(string),
+				Origin_: valid["origin"].(string),
+			}, nil
+		},
+	})
+
+	// We must allow for an empty value for fields introduced after v1
+	// because:
+	//   - newAddress always returns an address at the latest version
+	//   - newAddress is called by methods in Machine that do not negotiate
+	//     a version.
+	//
+	// If an old version of Juju not supporting new fields upgrades to this
+	// version of the library, we need to allow export and import of V2
+	// addresses that tolerate a missing space ID or CIDR.
+	// Ensuring correct defaults for this field must be ensured in the Juju
+	// migration code itself.
+	v2Fields, v2Defaults := builder.Extends(1)
+	v2Fields["spaceid"] = schema.String()
+	v2Defaults["spaceid"] = "" // must be allowed empty
+	builder.Register(schemamigrate.Registration[*address]{
+		Version:  2,
+		Fields:   v2Fields,
+		Defaults: v2Defaults,
+		Build: func(valid map[string]interface{}) (*address, error) {
+			return &address{
+				Version:  2,
+				Value_:   valid["value"].(string),
+				Type_:    valid["type"].(string),
+				Scope_:   valid["scope"].(string),
+				Origin_:  valid["origin"].(string),
+				SpaceID_: valid["spaceid"].(string),
+			}, nil
+		},
+	})
+
+	v3Fields, v3Defaults := builder.Extends(2)
+	v3Fields["cidr"] = schema.String()
+	v3Defaults["cidr"] = "" // must be allowed empty
+	builder.Register(schemamigrate.Registration[*address]{
+		Version:  3,
+		Fields:   v3Fields,
+		Defaults: v3Defaults,
+		Build: func(valid map[string]interface{}) (*address, error) {
+			result := &address{
+				Version:  3,
+				Value_:   valid["value"].(string),
+				Type_:    valid["type"].(string),
+				Scope_:   valid["scope"].(string),
+				Origin_:  valid["origin"].(string),
+				SpaceID_: valid["spaceid"].(string),
+				CIDR_:    valid["cidr"].(string),
+			}
+			if err := result.Validate(); err != nil {
+				return nil, errors.Trace(err)
+			}
+			return result, nil
+		},
+	})
+
+	// We allow for an empty UUID for documents imported from before this
+	// field existed, mirroring the treatment of spaceid and cidr above: old
+	// exports simply don't carry the identifier, and callers that need a
+	// stable correlation key must tolerate its absence for historical data.
+	v4Fields, v4Defaults := builder.Extends(3)
+	v4Fields["uuid"] = schema.String()
+	v4Defaults["uuid"] = "" // must be allowed empty
+	builder.Register(schemamigrate.Registration[*address]{
+		Version:  4,
+		Fields:   v4Fields,
+		Defaults: v4Defaults,
+		Build: func(valid map[string]interface{}) (*address, error) {
+			result := &address{
+				Version:  4,
+				Value_:   valid["value"].(string),
+				Type_:    valid["type"].(string),
+				Scope_:   valid["scope"].(string),
+				Origin_:  valid["origin"].(string),
+				SpaceID_: valid["spaceid"].(string),
+				CIDR_:    valid["cidr"].(string),
+				UUID_:    valid["uuid"].(string),
+			}
+			if err := result.Validate(); err != nil {
+				return nil, errors.Trace(err)
+			}
+			return result, nil
+		},
+	})
+
+	return builder.Build()
+}
 
-var addressDeserializationFuncs = map[int]addressDeserializationFunc{
-	1: importAddressV1,
-	2: importAddressV2,
-	3: importAddressV3,
+// internalScopePreference orders scopes from most to least preferred when
+// picking an address to use for intra-cloud communication, matching the
+// ordering Juju uses elsewhere when deciding which address to dial. Public
+// scope is deliberately excluded: a public address is never what's wanted
+// for internal traffic, even when it's the only address available.
+var internalScopePreference = []AddressScope{
+	ScopeCloudLocal,
+	ScopeMachineLocal,
+	ScopeLinkLocal,
 }
 
-func importAddressV1(source map[string]interface{}) (*address, error) {
-	fields, defaults := addressV1Fields()
-	checker := schema.FieldMap(fields, defaults)
+// Addresses is a versioned collection of Address values. The Version field
+// is serialised at the collection level so that future changes to how
+// addresses are grouped can be versioned without touching every consumer
+// that embeds an address list.
+type Addresses struct {
+	Version    int        `yaml:"version"`
+	Addresses_ []*address `yaml:"addresses"`
+}
 
-	coerced, err := checker.Coerce(source, nil)
-	if err != nil {
-		return nil, errors.Annotatef(err, "address v1 schema check failed")
+// newAddresses wraps the given addresses in the current Addresses version.
+func newAddresses(addresses []*address) Addresses {
+	return Addresses{
+		Version:    1,
+		Addresses_: addresses,
 	}
-	valid := coerced.(map[string]interface{})
-	// From here we know that the map returned from the schema coercion
-	// contains fields of the right type.
+}
 
-	return &address{
-		Version: 1,
-		Value_:  valid["value"].(string),
-		Type_:   valid["type"].(string),
-		Scope_:  valid["scope"].(string),
-		Origin_: valid["origin"].(string),
-	}, nil
+// List returns the addresses in the collection.
+func (a Addresses) List() []Address {
+	result := make([]Address, len(a.Addresses_))
+	for i, addr := range a.Addresses_ {
+		result[i] = addr
+	}
+	return result
 }
 
-func importAddressV2(source map[string]interface{}) (*address, error) {
-	fields, defaults := addressV2Fields()
-	checker := schema.FieldMap(fields, defaults)
+// ByCIDR returns the addresses in the collection whose CIDR matches cidr.
+func (a Addresses) ByCIDR(cidr string) []Address {
+	var result []Address
+	for _, addr := range a.Addresses_ {
+		if addr.CIDR_ == cidr {
+			result = append(result, addr)
+		}
+	}
+	return result
+}
 
-	coerced, err := checker.Coerce(source, nil)
-	if err != nil {
-		return nil, errors.Annotatef(err, "address v2 schema check failed")
+// BySpaceID returns the addresses in the collection belonging to the space
+// with the given ID.
+func (a Addresses) BySpaceID(id string) []Address {
+	var result []Address
+	for _, addr := range a.Addresses_ {
+		if addr.SpaceID_ == id {
+			result = append(result, addr)
+		}
 	}
-	valid := coerced.(map[string]interface{})
-	// From here we know that the map returned from the schema coercion
-	// contains fields of the right type.
+	return result
+}
 
-	return &address{
-		Version:  2,
-		Value_:   valid["value"].(string),
-		Type_:    valid["type"].(string),
-		Scope_:   valid["scope"].(string),
-		Origin_:  valid["origin"].(string),
-		SpaceID_: valid["spaceid"].(string),
-	}, nil
+// PublicAddresses returns the addresses in the collection with public scope.
+func (a Addresses) PublicAddresses() []Address {
+	var result []Address
+	for _, addr := range a.Addresses_ {
+		if AddressScope(addr.Scope_) == ScopePublic {
+			result = append(result, addr)
+		}
+	}
+	return result
 }
 
-func importAddressV3(source map[string]interface{}) (*address, error) {
-	fields, defaults := addressV3Fields()
-	checker := schema.FieldMap(fields, defaults)
+// PreferredPublic returns the most preferred public address in the
+// collection, or nil if there isn't one.
+func (a Addresses) PreferredPublic() Address {
+	for _, addr := range a.Addresses_ {
+		if AddressScope(addr.Scope_) == ScopePublic {
+			return addr
+		}
+	}
+	return nil
+}
 
-	coerced, err := checker.Coerce(source, nil)
-	if err != nil {
-		return nil, errors.Annotatef(err, "address v3 schema check failed")
+// PreferredInternal returns the best address to use for internal
+// communication, preferring cloud-local scope over machine-local and
+// link-local. Public addresses are never returned, even as a fallback,
+// since they are never appropriate for intra-cloud traffic; if every
+// address in the collection is public, PreferredInternal returns nil.
+func (a Addresses) PreferredInternal() Address {
+	for _, scope := range internalScopePreference {
+		for _, addr := range a.Addresses_ {
+			if AddressScope(addr.Scope_) == scope {
+				return addr
+			}
+		}
 	}
-	valid := coerced.(map[string]interface{})
-	// From here we know that the map returned from the schema coercion
-	// contains fields of the right type.
+	return nil
+}
 
-	return &address{
-		Version:  3,
-		Value_:   valid["value"].(string),
-		Type_:    valid["type"].(string),
-		Scope_:   valid["scope"].(string),
-		Origin_:  valid["origin"].(string),
-		SpaceID_: valid["spaceid"].(string),
-		CIDR_:    valid["cidr"].(string),
-	}, nil
-}
-
-func addressV1Fields() (schema.Fields, schema.Defaults) {
-	fields := schema.Fields{
-		"value":  schema.String(),
-		"type":   schema.String(),
-		"scope":  schema.String(),
-		"origin": schema.String(),
-	}
-	// Some values don't have to be there.
-	defaults := schema.Defaults{
-		"scope":  "",
-		"origin": "",
-	}
-	return fields, defaults
-}
-
-// We must allow for an empty value for fields introduced after v1 because:
-//   - newAddress always returns an address at the latest version
-//   - newAddress is called by methods in Machine that do not negotiate a
-//     version.
+// Merge combines a with other, deduplicating by UUID - or, for pre-v4
+// addresses that don't carry one, by the (Value, SpaceID) tuple - and
+// preferring the address with the newer Origin when both collections
+// contain an entry for the same key. Origin is considered newer when it is
+// OriginMachine, since machine-reported addresses supersede those reported
+// by the provider.
 //
-// If an old version of Juju not supporting new fields upgrades to this
-// version of the library, we need to allow export and import of V2
-// addresses that tolerate a missing space ID or CIDR.
-// Ensuring correct defaults for this field must be ensured in the Juju
-// migration code itself.
-
-func addressV2Fields() (schema.Fields, schema.Defaults) {
-	fields, defaults := addressV1Fields()
-	fields["spaceid"] = schema.String()
-	defaults["spaceid"] = "" // must be allowed empty
-	return fields, defaults
-}
-
-func addressV3Fields() (schema.Fields, schema.Defaults) {
-	fields, defaults := addressV2Fields()
-	fields["cidr"] = schema.String()
-	defaults["cidr"] = "" // must be allowed empty
-	return fields, defaults
+// UUID is used as the primary key because (Value, SpaceID) alone is not
+// unique: the same IP can appear on multiple machines across separate
+// NICs, which is exactly the ambiguity UUID was introduced to resolve.
+func (a Addresses) Merge(other Addresses) Addresses {
+	type key struct {
+		uuid    string
+		value   string
+		spaceID string
+	}
+
+	keyFor := func(addr *address) key {
+		if addr.UUID_ != "" {
+			return key{uuid: addr.UUID_}
+		}
+		return key{value: addr.Value_, spaceID: addr.SpaceID_}
+	}
+
+	byKey := make(map[key]*address)
+	order := make([]key, 0, len(a.Addresses_)+len(other.Addresses_))
+
+	add := func(addr *address) {
+		k := keyFor(addr)
+		existing, found := byKey[k]
+		if !found {
+			byKey[k] = addr
+			order = append(order, k)
+			return
+		}
+		if AddressOrigin(addr.Origin_) == OriginMachine && AddressOrigin(existing.Origin_) != OriginMachine {
+			byKey[k] = addr
+		}
+	}
+
+	for _, addr := range a.Addresses_ {
+		add(addr)
+	}
+	for _, addr := range other.Addresses_ {
+		add(addr)
+	}
+
+	merged := make([]*address, len(order))
+	for i, k := range order {
+		merged[i] = byKey[k]
+	}
+	return newAddresses(merged)
 }
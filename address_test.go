@@ -0,0 +1,215 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package description
+
+import "testing"
+
+func validAddressArgs() AddressArgs {
+	return AddressArgs{
+		Value:   "10.0.0.1",
+		Type:    AddressTypeIPv4,
+		Scope:   ScopeCloudLocal,
+		Origin:  OriginMachine,
+		SpaceID: "42",
+		CIDR:    "10.0.0.0/24",
+		UUID:    "address-uuid",
+	}
+}
+
+func TestAddressValidateOK(t *testing.T) {
+	addr := newAddress(validAddressArgs())
+	if err := addr.Validate(); err != nil {
+		t.Fatalf("expected valid address, got %v", err)
+	}
+}
+
+func TestAddressValidateUnknownType(t *testing.T) {
+	args := validAddressArgs()
+	args.Type = "IPV4"
+	addr := newAddress(args)
+	if err := addr.Validate(); err == nil {
+		t.Fatal("expected error for unknown address type, got nil")
+	}
+}
+
+func TestAddressValidateUnknownScope(t *testing.T) {
+	args := validAddressArgs()
+	args.Scope = "bogus"
+	addr := newAddress(args)
+	if err := addr.Validate(); err == nil {
+		t.Fatal("expected error for unknown address scope, got nil")
+	}
+}
+
+func TestAddressValidateUnknownOrigin(t *testing.T) {
+	args := validAddressArgs()
+	args.Origin = "bogus"
+	addr := newAddress(args)
+	if err := addr.Validate(); err == nil {
+		t.Fatal("expected error for unknown address origin, got nil")
+	}
+}
+
+func TestAddressValidateValueDoesNotParse(t *testing.T) {
+	args := validAddressArgs()
+	args.Value = "not-an-ip"
+	addr := newAddress(args)
+	if err := addr.Validate(); err == nil {
+		t.Fatal("expected error for unparsable IPv4 value, got nil")
+	}
+}
+
+func TestAddressValidateValueNotInCIDR(t *testing.T) {
+	args := validAddressArgs()
+	args.CIDR = "192.168.0.0/24"
+	addr := newAddress(args)
+	if err := addr.Validate(); err == nil {
+		t.Fatal("expected error for value outside CIDR, got nil")
+	}
+}
+
+func TestAddressValidateHostnameSkipsIPChecks(t *testing.T) {
+	args := validAddressArgs()
+	args.Type = AddressTypeHostname
+	args.Value = "foo.internal"
+	args.CIDR = ""
+	addr := newAddress(args)
+	if err := addr.Validate(); err != nil {
+		t.Fatalf("expected hostname address to validate, got %v", err)
+	}
+}
+
+func TestAddressArgsValidateMatchesAddressValidate(t *testing.T) {
+	args := validAddressArgs()
+	args.Value = "not-an-ip"
+	if err := args.Validate(); err == nil {
+		t.Fatal("expected AddressArgs.Validate to reject the same bad value, got nil")
+	}
+}
+
+// TestAddressMigratorImportV1 confirms a v1 document - the oldest shape,
+// with no spaceid, cidr or uuid fields at all - still imports, with those
+// later fields defaulting to empty.
+func TestAddressMigratorImportV1(t *testing.T) {
+	addr, err := addressMigrator.Import(map[string]interface{}{
+		"value":  "10.0.0.1",
+		"type":   "ipv4",
+		"scope":  "local-cloud",
+		"origin": "machine",
+	}, 1)
+	if err != nil {
+		t.Fatalf("v1 import failed: %v", err)
+	}
+	if addr.Version != 1 {
+		t.Fatalf("expected version 1, got %d", addr.Version)
+	}
+	if addr.SpaceID() != "" || addr.CIDR() != "" || addr.UUID() != "" {
+		t.Fatalf("expected empty spaceid/cidr/uuid defaults, got %#v", addr)
+	}
+}
+
+// TestAddressMigratorImportV2 confirms a v2 document round-trips with a
+// spaceid but still defaults cidr and uuid to empty.
+func TestAddressMigratorImportV2(t *testing.T) {
+	addr, err := addressMigrator.Import(map[string]interface{}{
+		"value":   "10.0.0.1",
+		"type":    "ipv4",
+		"scope":   "local-cloud",
+		"origin":  "machine",
+		"spaceid": "7",
+	}, 2)
+	if err != nil {
+		t.Fatalf("v2 import failed: %v", err)
+	}
+	if addr.Version != 2 {
+		t.Fatalf("expected version 2, got %d", addr.Version)
+	}
+	if addr.SpaceID() != "7" {
+		t.Fatalf("expected spaceid 7, got %q", addr.SpaceID())
+	}
+	if addr.CIDR() != "" || addr.UUID() != "" {
+		t.Fatalf("expected empty cidr/uuid defaults, got %#v", addr)
+	}
+}
+
+// TestAddressMigratorImportV3 confirms a v3 document round-trips with a
+// cidr but still defaults uuid to empty - the case chunk0-1 explicitly
+// needed to keep working when it introduced the uuid field in v4.
+func TestAddressMigratorImportV3(t *testing.T) {
+	addr, err := addressMigrator.Import(map[string]interface{}{
+		"value":   "10.0.0.1",
+		"type":    "ipv4",
+		"scope":   "local-cloud",
+		"origin":  "machine",
+		"spaceid": "7",
+		"cidr":    "10.0.0.0/24",
+	}, 3)
+	if err != nil {
+		t.Fatalf("v3 import failed: %v", err)
+	}
+	if addr.Version != 3 {
+		t.Fatalf("expected version 3, got %d", addr.Version)
+	}
+	if addr.CIDR() != "10.0.0.0/24" {
+		t.Fatalf("expected cidr 10.0.0.0/24, got %q", addr.CIDR())
+	}
+	if addr.UUID() != "" {
+		t.Fatalf("expected empty uuid default for a v3 document, got %q", addr.UUID())
+	}
+}
+
+// TestAddressMigratorImportV4 confirms a v4 document round-trips its uuid.
+func TestAddressMigratorImportV4(t *testing.T) {
+	addr, err := addressMigrator.Import(map[string]interface{}{
+		"value":   "10.0.0.1",
+		"type":    "ipv4",
+		"scope":   "local-cloud",
+		"origin":  "machine",
+		"spaceid": "7",
+		"cidr":    "10.0.0.0/24",
+		"uuid":    "address-uuid",
+	}, 4)
+	if err != nil {
+		t.Fatalf("v4 import failed: %v", err)
+	}
+	if addr.UUID() != "address-uuid" {
+		t.Fatalf("expected uuid address-uuid, got %q", addr.UUID())
+	}
+}
+
+// TestAddressMigratorImportV3RejectsUnknownScope confirms Validate is
+// still wired into the v3 path after being routed through the Migrator.
+func TestAddressMigratorImportV3RejectsUnknownScope(t *testing.T) {
+	_, err := addressMigrator.Import(map[string]interface{}{
+		"value":   "10.0.0.1",
+		"type":    "ipv4",
+		"scope":   "bogus",
+		"origin":  "machine",
+		"spaceid": "7",
+		"cidr":    "10.0.0.0/24",
+	}, 3)
+	if err == nil {
+		t.Fatal("expected v3 import to reject an unknown scope, got nil")
+	}
+}
+
+// TestImportAddressDispatchesOnVersionField confirms importAddress (as
+// opposed to calling the Migrator directly) picks the right version purely
+// from the document's "version" field, which is how every real caller
+// reaches the Migrator.
+func TestImportAddressDispatchesOnVersionField(t *testing.T) {
+	addr, err := importAddress(map[string]interface{}{
+		"version": 1,
+		"value":   "10.0.0.1",
+		"type":    "ipv4",
+		"scope":   "local-cloud",
+		"origin":  "machine",
+	})
+	if err != nil {
+		t.Fatalf("importAddress failed: %v", err)
+	}
+	if addr.Version != 1 || addr.SpaceID() != "" || addr.CIDR() != "" || addr.UUID() != "" {
+		t.Fatalf("unexpected result for a v1 document: %#v", addr)
+	}
+}
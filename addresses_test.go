@@ -0,0 +1,127 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package description
+
+import "testing"
+
+func addrWith(t *testing.T, mutate func(*AddressArgs)) *address {
+	t.Helper()
+	args := validAddressArgs()
+	if mutate != nil {
+		mutate(&args)
+	}
+	return newAddress(args)
+}
+
+func TestAddressesPreferredPublic(t *testing.T) {
+	public := addrWith(t, func(a *AddressArgs) { a.Scope = ScopePublic; a.Value = "1.2.3.4" })
+	internal := addrWith(t, func(a *AddressArgs) { a.Scope = ScopeCloudLocal; a.Value = "10.0.0.1" })
+	addrs := newAddresses([]*address{internal, public})
+
+	got := addrs.PreferredPublic()
+	if got == nil || got.Value() != "1.2.3.4" {
+		t.Fatalf("expected the public address, got %v", got)
+	}
+}
+
+func TestAddressesPreferredInternalExcludesPublic(t *testing.T) {
+	public := addrWith(t, func(a *AddressArgs) { a.Scope = ScopePublic; a.Value = "1.2.3.4" })
+	addrs := newAddresses([]*address{public})
+
+	if got := addrs.PreferredInternal(); got != nil {
+		t.Fatalf("expected no internal address when only a public one exists, got %v", got)
+	}
+}
+
+func TestAddressesPreferredInternalOrdersByScope(t *testing.T) {
+	linkLocal := addrWith(t, func(a *AddressArgs) { a.Scope = ScopeLinkLocal; a.Value = "169.254.0.1" })
+	cloudLocal := addrWith(t, func(a *AddressArgs) { a.Scope = ScopeCloudLocal; a.Value = "10.0.0.1" })
+	addrs := newAddresses([]*address{linkLocal, cloudLocal})
+
+	got := addrs.PreferredInternal()
+	if got == nil || got.Value() != "10.0.0.1" {
+		t.Fatalf("expected the cloud-local address, got %v", got)
+	}
+}
+
+func TestAddressesMergeDedupesByUUID(t *testing.T) {
+	older := addrWith(t, func(a *AddressArgs) {
+		a.UUID = "shared-uuid"
+		a.Value = "10.0.0.1"
+		a.SpaceID = "1"
+		a.Origin = OriginProvider
+	})
+	newer := addrWith(t, func(a *AddressArgs) {
+		a.UUID = "shared-uuid"
+		a.Value = "10.0.0.2" // same address, value changed between snapshots
+		a.SpaceID = "2"
+		a.Origin = OriginMachine
+	})
+
+	merged := newAddresses([]*address{older}).Merge(newAddresses([]*address{newer}))
+
+	list := merged.List()
+	if len(list) != 1 {
+		t.Fatalf("expected a single merged address, got %d", len(list))
+	}
+	if list[0].Value() != "10.0.0.2" || list[0].Origin() != OriginMachine {
+		t.Fatalf("expected the machine-origin entry to win, got %#v", list[0])
+	}
+}
+
+func TestAddressesMergeKeepsDistinctAddressesSharingValueAndSpace(t *testing.T) {
+	// Same IP/space but different UUIDs: two distinct NICs on different
+	// machines, which is exactly the ambiguity UUID was added to resolve.
+	first := addrWith(t, func(a *AddressArgs) {
+		a.UUID = "uuid-1"
+		a.Value = "10.0.0.1"
+		a.SpaceID = "1"
+	})
+	second := addrWith(t, func(a *AddressArgs) {
+		a.UUID = "uuid-2"
+		a.Value = "10.0.0.1"
+		a.SpaceID = "1"
+	})
+
+	merged := newAddresses([]*address{first}).Merge(newAddresses([]*address{second}))
+
+	if len(merged.List()) != 2 {
+		t.Fatalf("expected both addresses to survive the merge, got %d", len(merged.List()))
+	}
+}
+
+func TestAddressesMergeFallsBackToValueSpaceIDWithoutUUID(t *testing.T) {
+	older := addrWith(t, func(a *AddressArgs) {
+		a.UUID = ""
+		a.Value = "10.0.0.1"
+		a.SpaceID = "1"
+		a.Origin = OriginProvider
+	})
+	newer := addrWith(t, func(a *AddressArgs) {
+		a.UUID = ""
+		a.Value = "10.0.0.1"
+		a.SpaceID = "1"
+		a.Origin = OriginMachine
+	})
+
+	merged := newAddresses([]*address{older}).Merge(newAddresses([]*address{newer}))
+
+	list := merged.List()
+	if len(list) != 1 || list[0].Origin() != OriginMachine {
+		t.Fatalf("expected the two pre-v4 addresses to dedupe by value/spaceid, got %#v", list)
+	}
+}
+
+func TestAddressesByCIDRAndBySpaceID(t *testing.T) {
+	a := addrWith(t, func(a *AddressArgs) { a.CIDR = "10.0.0.0/24"; a.SpaceID = "1" })
+	b := addrWith(t, func(a *AddressArgs) { a.CIDR = "10.0.1.0/24"; a.SpaceID = "2"; a.Value = "10.0.1.1" })
+	addrs := newAddresses([]*address{a, b})
+
+	if got := addrs.ByCIDR("10.0.0.0/24"); len(got) != 1 || got[0] != Address(a) {
+		t.Fatalf("ByCIDR returned unexpected result: %#v", got)
+	}
+	if got := addrs.BySpaceID("2"); len(got) != 1 || got[0] != Address(b) {
+		t.Fatalf("BySpaceID returned unexpected result: %#v", got)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+// Package schemamigrate factors out the versioned schema-import dance that
+// recurs throughout the description package: a getVersion check, a map of
+// per-version import functions, a schema.FieldMap built from fields that
+// extend the previous version's, and a coerce-then-build step. Migrator
+// centralises everything but the getVersion check, so each type only needs
+// to describe its versions; reading the version from the document is left
+// to the caller, which passes it to Migrator.Import.
+package schemamigrate
+
+import (
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+)
+
+// Registration describes how to import a single version of a document: the
+// schema fields and defaults for that version, and how to build the target
+// type T from the coerced result.
+type Registration[T any] struct {
+	Version  int
+	Fields   schema.Fields
+	Defaults schema.Defaults
+	Build    func(map[string]interface{}) (T, error)
+}
+
+// Migrator dispatches a versioned, schema-checked import to the
+// Registration matching the version passed to Import, memoizing the
+// schema.Checker built for each version so that importing many elements of
+// the same version (for example a large list of addresses) doesn't rebuild
+// the same FieldMap checker per element.
+type Migrator[T any] struct {
+	registrations map[int]Registration[T]
+
+	mu       sync.Mutex
+	checkers map[int]schema.Checker
+}
+
+// Builder accumulates per-version Registrations and lets later versions
+// extend the fields of an earlier one before producing a Migrator.
+type Builder[T any] struct {
+	registrations []Registration[T]
+}
+
+// NewBuilder returns an empty Builder for type T.
+func NewBuilder[T any]() *Builder[T] {
+	return &Builder[T]{}
+}
+
+// Register adds a version to the builder. Versions may be registered in any
+// order; the Migrator dispatches purely on the Registration.Version field.
+func (b *Builder[T]) Register(reg Registration[T]) *Builder[T] {
+	b.registrations = append(b.registrations, reg)
+	return b
+}
+
+// Extends returns copies of the fields and defaults registered under
+// previousVersion, so a later version can start from its predecessor's
+// schema and only describe what it adds or changes. Mutating the returned
+// maps does not affect the stored registration.
+func (b *Builder[T]) Extends(previousVersion int) (schema.Fields, schema.Defaults) {
+	for _, reg := range b.registrations {
+		if reg.Version != previousVersion {
+			continue
+		}
+		fields := make(schema.Fields, len(reg.Fields))
+		for k, v := range reg.Fields {
+			fields[k] = v
+		}
+		defaults := make(schema.Defaults, len(reg.Defaults))
+		for k, v := range reg.Defaults {
+			defaults[k] = v
+		}
+		return fields, defaults
+	}
+	return schema.Fields{}, schema.Defaults{}
+}
+
+// Build finalises the Migrator from the registrations accumulated so far.
+func (b *Builder[T]) Build() *Migrator[T] {
+	byVersion := make(map[int]Registration[T], len(b.registrations))
+	for _, reg := range b.registrations {
+		byVersion[reg.Version] = reg
+	}
+	return &Migrator[T]{
+		registrations: byVersion,
+		checkers:      make(map[int]schema.Checker),
+	}
+}
+
+// Import coerces source against the schema registered for version and
+// builds the resulting T. It does not read a "version" field from source;
+// the caller determines the version and passes it in.
+func (m *Migrator[T]) Import(source map[string]interface{}, version int) (T, error) {
+	var zero T
+	reg, ok := m.registrations[version]
+	if !ok {
+		return zero, errors.NotValidf("version %d", version)
+	}
+
+	coerced, err := m.checkerFor(reg).Coerce(source, nil)
+	if err != nil {
+		return zero, errors.Annotatef(err, "v%d schema check failed", version)
+	}
+	// From here we know that the map returned from the schema coercion
+	// contains fields of the right type.
+	valid := coerced.(map[string]interface{})
+
+	result, err := reg.Build(valid)
+	if err != nil {
+		return zero, errors.Trace(err)
+	}
+	return result, nil
+}
+
+// checkerFor returns the memoized schema.Checker for reg, building it on
+// first use.
+func (m *Migrator[T]) checkerFor(reg Registration[T]) schema.Checker {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if checker, ok := m.checkers[reg.Version]; ok {
+		return checker
+	}
+	checker := schema.FieldMap(reg.Fields, reg.Defaults)
+	m.checkers[reg.Version] = checker
+	return checker
+}
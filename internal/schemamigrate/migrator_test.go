@@ -0,0 +1,106 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package schemamigrate
+
+import (
+	"testing"
+
+	"github.com/juju/schema"
+)
+
+type widget struct {
+	Version int
+	Name    string
+	Size    string
+}
+
+func newWidgetMigrator() *Migrator[*widget] {
+	builder := NewBuilder[*widget]()
+
+	builder.Register(Registration[*widget]{
+		Version: 1,
+		Fields: schema.Fields{
+			"name": schema.String(),
+		},
+		Defaults: schema.Defaults{},
+		Build: func(valid map[string]interface{}) (*widget, error) {
+			return &widget{Version: 1, Name: valid["name"].(string)}, nil
+		},
+	})
+
+	v2Fields, v2Defaults := builder.Extends(1)
+	v2Fields["size"] = schema.String()
+	v2Defaults["size"] = ""
+	builder.Register(Registration[*widget]{
+		Version:  2,
+		Fields:   v2Fields,
+		Defaults: v2Defaults,
+		Build: func(valid map[string]interface{}) (*widget, error) {
+			return &widget{Version: 2, Name: valid["name"].(string), Size: valid["size"].(string)}, nil
+		},
+	})
+
+	return builder.Build()
+}
+
+func TestMigratorImportDispatchesByVersion(t *testing.T) {
+	m := newWidgetMigrator()
+
+	v1, err := m.Import(map[string]interface{}{"name": "gizmo"}, 1)
+	if err != nil {
+		t.Fatalf("v1 import failed: %v", err)
+	}
+	if v1.Name != "gizmo" || v1.Size != "" {
+		t.Fatalf("unexpected v1 result: %#v", v1)
+	}
+
+	v2, err := m.Import(map[string]interface{}{"name": "gizmo", "size": "large"}, 2)
+	if err != nil {
+		t.Fatalf("v2 import failed: %v", err)
+	}
+	if v2.Name != "gizmo" || v2.Size != "large" {
+		t.Fatalf("unexpected v2 result: %#v", v2)
+	}
+}
+
+func TestMigratorExtendsCopiesFields(t *testing.T) {
+	m := newWidgetMigrator()
+
+	// v1 documents must still import correctly after v2's Extends(1) call
+	// added a size field to the maps it returned.
+	v1, err := m.Import(map[string]interface{}{"name": "gizmo"}, 1)
+	if err != nil {
+		t.Fatalf("v1 import failed after v2 registration: %v", err)
+	}
+	if v1.Size != "" {
+		t.Fatalf("expected v1 to have no size field, got %#v", v1)
+	}
+
+	// The import above succeeds even if the maps were shared, so check the
+	// copies directly: mutating what Extends returned must not show up in a
+	// later Extends of the same version.
+	b := NewBuilder[*widget]()
+	b.Register(Registration[*widget]{
+		Version:  1,
+		Fields:   schema.Fields{"name": schema.String()},
+		Defaults: schema.Defaults{},
+	})
+	fields, defaults := b.Extends(1)
+	fields["size"] = schema.String()
+	defaults["size"] = ""
+	again, againDefaults := b.Extends(1)
+	if _, ok := again["size"]; ok {
+		t.Fatal("expected Extends to return a copy of the fields, but v1 gained size")
+	}
+	if _, ok := againDefaults["size"]; ok {
+		t.Fatal("expected Extends to return a copy of the defaults, but v1 gained size")
+	}
+}
+
+func TestMigratorImportUnknownVersion(t *testing.T) {
+	m := newWidgetMigrator()
+	if _, err := m.Import(map[string]interface{}{"name": "gizmo"}, 99); err == nil {
+		t.Fatal("expected an error for an unregistered version, got nil")
+	}
+}
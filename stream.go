@@ -0,0 +1,184 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package description
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/juju/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ImportEvent is a single Address emitted by ImportAddressesStream as soon
+// as it is parsed, before the rest of the "addresses" list has been walked.
+type ImportEvent struct {
+	Address Address
+}
+
+// Event is a single Address to be written by ExportAddressesStream.
+type Event struct {
+	Address Address
+}
+
+// ImportAddressesStream decodes a single YAML document from r shaped like
+// the existing Addresses document - a map with an "addresses" key holding
+// the same list importAddresses already walks - and emits each element as
+// an ImportEvent as soon as it is decoded.
+//
+// Parsing the document still requires one pass over its bytes to build a
+// yaml.Node syntax tree (unavoidable: we need to find the "addresses" key
+// before we can do anything with it), but unlike importAddresses([]interface{}),
+// that tree is never converted into a fully generic []interface{} up front.
+// Each element of the addresses sequence is decoded into its own
+// map[string]interface{} and imported one at a time, so at most one
+// decoded address is ever held in memory - the dominant allocator on
+// models with tens of thousands of addresses, for example large OpenStack
+// deployments where every unit has multiple NICs each carrying several
+// scoped addresses, is the per-element conversion, not the syntax parse.
+//
+// The returned error channel receives at most one error. Both channels are
+// closed once decoding stops, the error channel just before the event
+// channel, so any error is already buffered when the event channel closes.
+func ImportAddressesStream(r io.Reader) (<-chan ImportEvent, <-chan error) {
+	events := make(chan ImportEvent)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		var doc yaml.Node
+		if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+			errs <- errors.Trace(err)
+			return
+		}
+
+		addressesNode, err := mappingValue(&doc, "addresses")
+		if err != nil {
+			errs <- errors.Trace(err)
+			return
+		}
+		if addressesNode == nil {
+			return
+		}
+		if addressesNode.Kind != yaml.SequenceNode {
+			errs <- errors.Errorf("unexpected node kind %v for addresses", addressesNode.Kind)
+			return
+		}
+
+		for i, elementNode := range addressesNode.Content {
+			var source map[string]interface{}
+			if err := elementNode.Decode(&source); err != nil {
+				errs <- errors.Annotatef(err, "address %d", i)
+				return
+			}
+			addr, err := importAddress(source)
+			if err != nil {
+				errs <- errors.Trace(err)
+				return
+			}
+			events <- ImportEvent{Address: addr}
+		}
+	}()
+
+	return events, errs
+}
+
+// mappingValue returns the value node for key within doc's top-level
+// mapping, or nil if key is absent.
+func mappingValue(doc *yaml.Node, key string) (*yaml.Node, error) {
+	root := doc
+	if root.Kind == yaml.DocumentNode {
+		if len(root.Content) == 0 {
+			return nil, nil
+		}
+		root = root.Content[0]
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.Errorf("unexpected node kind %v for top-level document", root.Kind)
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			return root.Content[i+1], nil
+		}
+	}
+	return nil, nil
+}
+
+// ExportAddressesStream writes a single YAML document shaped like the
+// existing Addresses document to w: an "addresses:" key followed by one
+// list item per Address received on events, written out as soon as it
+// arrives. Unlike marshalling a fully assembled Addresses value in one
+// call, this never holds more than one address's encoded form in memory
+// at a time - each item is marshalled and written to w, then discarded,
+// before the next one is read from events.
+func ExportAddressesStream(w io.Writer, events <-chan Event) error {
+	wroteHeader := false
+	for event := range events {
+		if event.Address == nil {
+			continue
+		}
+		addr, ok := event.Address.(*address)
+		if !ok {
+			return errors.Errorf("unexpected address implementation %T", event.Address)
+		}
+		if !wroteHeader {
+			if _, err := io.WriteString(w, "addresses:\n"); err != nil {
+				return errors.Trace(err)
+			}
+			wroteHeader = true
+		}
+		if err := writeAddressListItem(w, addr); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if !wroteHeader {
+		_, err := io.WriteString(w, "addresses: []\n")
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// writeAddressListItem marshals addr on its own and writes it to w as a
+// single "- "-prefixed YAML sequence item, indented to line up under the
+// addresses key written by ExportAddressesStream.
+func writeAddressListItem(w io.Writer, addr *address) error {
+	encoded, err := yaml.Marshal(addr)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	lines := strings.Split(strings.TrimRight(string(encoded), "\n"), "\n")
+	for i, line := range lines {
+		prefix := "    "
+		if i == 0 {
+			prefix = "  - "
+		}
+		if _, err := io.WriteString(w, prefix+line+"\n"); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// ImportAddresses decodes a full Addresses document from data in one call.
+// It is a thin wrapper around ImportAddressesStream for callers that don't
+// need the streaming path and just want the assembled result.
+func ImportAddresses(data []byte) (Addresses, error) {
+	events, errs := ImportAddressesStream(bytes.NewReader(data))
+
+	var result []*address
+	for event := range events {
+		addr, ok := event.Address.(*address)
+		if !ok {
+			return Addresses{}, errors.Errorf("unexpected address implementation %T", event.Address)
+		}
+		result = append(result, addr)
+	}
+	if err := <-errs; err != nil {
+		return Addresses{}, errors.Trace(err)
+	}
+	return newAddresses(result), nil
+}
@@ -0,0 +1,237 @@
+// Copyright 2026 Canonical Ltd.
+// Licensed under the LGPLv3, see LICENCE file for details.
+
+package description
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestImportAddressesStreamRoundTrip(t *testing.T) {
+	source := newAddresses([]*address{
+		newAddress(validAddressArgs()),
+		addrWith(t, func(a *AddressArgs) { a.Value = "10.0.0.2"; a.UUID = "uuid-2" }),
+	})
+
+	var buf bytes.Buffer
+	events := make(chan Event, len(source.Addresses_))
+	for _, addr := range source.Addresses_ {
+		events <- Event{Address: addr}
+	}
+	close(events)
+	if err := ExportAddressesStream(&buf, events); err != nil {
+		t.Fatalf("ExportAddressesStream failed: %v", err)
+	}
+
+	imported, errs := ImportAddressesStream(bytes.NewReader(buf.Bytes()))
+
+	var got []Address
+	for event := range imported {
+		got = append(got, event.Address)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("ImportAddressesStream failed: %v", err)
+	}
+
+	if len(got) != len(source.Addresses_) {
+		t.Fatalf("expected %d addresses, got %d", len(source.Addresses_), len(got))
+	}
+	for i, addr := range got {
+		if addr.Value() != source.Addresses_[i].Value_ {
+			t.Fatalf("address %d: expected value %q, got %q", i, source.Addresses_[i].Value_, addr.Value())
+		}
+		if addr.UUID() != source.Addresses_[i].UUID_ {
+			t.Fatalf("address %d: expected uuid %q, got %q", i, source.Addresses_[i].UUID_, addr.UUID())
+		}
+	}
+}
+
+func TestImportAddressesMatchesStream(t *testing.T) {
+	source := newAddresses([]*address{newAddress(validAddressArgs())})
+
+	var buf bytes.Buffer
+	events := make(chan Event, 1)
+	events <- Event{Address: source.Addresses_[0]}
+	close(events)
+	if err := ExportAddressesStream(&buf, events); err != nil {
+		t.Fatalf("ExportAddressesStream failed: %v", err)
+	}
+
+	result, err := ImportAddresses(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ImportAddresses failed: %v", err)
+	}
+	if len(result.Addresses_) != 1 || result.Addresses_[0].Value_ != "10.0.0.1" {
+		t.Fatalf("unexpected ImportAddresses result: %#v", result)
+	}
+}
+
+func TestImportAddressesStreamNoAddressesKey(t *testing.T) {
+	events, errs := ImportAddressesStream(bytes.NewReader([]byte("other: true\n")))
+
+	var got []Address
+	for event := range events {
+		got = append(got, event.Address)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("expected no error for a document without an addresses key, got %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no events, got %d", len(got))
+	}
+}
+
+// TestImportAddressesStreamLargeN round-trips a few thousand addresses, the
+// scale the streaming path exists for (large OpenStack deployments where
+// every unit has multiple NICs, each with several scoped addresses).
+func TestImportAddressesStreamLargeN(t *testing.T) {
+	const n = 5000
+
+	addrs := make([]*address, n)
+	for i := range addrs {
+		addrs[i] = addrWith(t, func(a *AddressArgs) {
+			a.Value = fmt.Sprintf("10.0.%d.%d", i/256, i%256)
+			a.CIDR = ""
+			a.UUID = fmt.Sprintf("uuid-%d", i)
+		})
+	}
+
+	var buf bytes.Buffer
+	events := make(chan Event, n)
+	for _, addr := range addrs {
+		events <- Event{Address: addr}
+	}
+	close(events)
+	if err := ExportAddressesStream(&buf, events); err != nil {
+		t.Fatalf("ExportAddressesStream failed: %v", err)
+	}
+
+	imported, errs := ImportAddressesStream(bytes.NewReader(buf.Bytes()))
+	count := 0
+	for event := range imported {
+		if event.Address.UUID() != fmt.Sprintf("uuid-%d", count) {
+			t.Fatalf("address %d: expected uuid-%d, got %s", count, count, event.Address.UUID())
+		}
+		count++
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("ImportAddressesStream failed: %v", err)
+	}
+	if count != n {
+		t.Fatalf("expected %d addresses, got %d", n, count)
+	}
+}
+
+// TestImportAddressesStreamEmitsPriorElementsBeforeFailingLater confirms
+// ImportAddressesStream decodes and imports addresses one at a time instead
+// of converting the whole "addresses" list up front and only then
+// reporting problems: an event for the first (valid) element must be
+// observed before the stream reports the error caused by the second,
+// malformed one.
+func TestImportAddressesStreamEmitsPriorElementsBeforeFailingLater(t *testing.T) {
+	doc := "addresses:\n" +
+		"  - value: 10.0.0.1\n" +
+		"    type: ipv4\n" +
+		"    scope: local-cloud\n" +
+		"    origin: machine\n" +
+		"    spaceid: \"1\"\n" +
+		"    cidr: 10.0.0.0/24\n" +
+		"    uuid: uuid-1\n" +
+		"    version: 4\n" +
+		"  - this-is-not-a-mapping\n"
+
+	events, errs := ImportAddressesStream(strings.NewReader(doc))
+
+	first, ok := <-events
+	if !ok {
+		t.Fatal("expected an event for the first, valid address before the stream fails")
+	}
+	if first.Address.Value() != "10.0.0.1" {
+		t.Fatalf("expected the first address to be 10.0.0.1, got %q", first.Address.Value())
+	}
+
+	if _, ok := <-events; ok {
+		t.Fatal("expected no event for the malformed second address")
+	}
+	if err := <-errs; err == nil {
+		t.Fatal("expected an error for the malformed second address, got nil")
+	}
+}
+
+// notifyWriter signals on wrote after every successful Write and guards the
+// underlying buffer with a mutex, letting a test observe (through String)
+// that bytes reached it without racing on it while the writer goroutine
+// may still be running.
+type notifyWriter struct {
+	mu    sync.Mutex
+	w     *bytes.Buffer
+	wrote chan struct{}
+}
+
+// String returns what has been written so far, under the same lock as Write.
+func (n *notifyWriter) String() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.w.String()
+}
+
+func (n *notifyWriter) Write(p []byte) (int, error) {
+	n.mu.Lock()
+	nn, err := n.w.Write(p)
+	n.mu.Unlock()
+	if nn > 0 {
+		select {
+		case n.wrote <- struct{}{}:
+		default:
+		}
+	}
+	return nn, err
+}
+
+// TestExportAddressesStreamWritesIncrementally confirms ExportAddressesStream
+// writes each address to w as it arrives on events, rather than buffering
+// every address and writing them all in one call once the channel closes.
+func TestExportAddressesStreamWritesIncrementally(t *testing.T) {
+	var buf bytes.Buffer
+	nw := &notifyWriter{w: &buf, wrote: make(chan struct{}, 64)}
+
+	events := make(chan Event)
+	done := make(chan error, 1)
+	go func() {
+		done <- ExportAddressesStream(nw, events)
+	}()
+
+	first := addrWith(t, func(a *AddressArgs) { a.Value = "10.0.0.1" })
+	events <- Event{Address: first}
+
+	deadline := time.After(5 * time.Second)
+	for !strings.Contains(nw.String(), "10.0.0.1") {
+		select {
+		case <-nw.wrote:
+		case <-deadline:
+			t.Fatal("timed out waiting for the first address to be written")
+		}
+	}
+	// The exporter is blocked receiving on the unbuffered events channel and
+	// the second address has not been sent yet; the buffer must not contain
+	// it before it has even been sent.
+	if strings.Contains(nw.String(), "10.0.0.2") {
+		t.Fatal("second address was written before it was sent on events")
+	}
+
+	second := addrWith(t, func(a *AddressArgs) { a.Value = "10.0.0.2" })
+	events <- Event{Address: second}
+	close(events)
+
+	if err := <-done; err != nil {
+		t.Fatalf("ExportAddressesStream failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "10.0.0.2") {
+		t.Fatal("expected the second address to be written after it was sent")
+	}
+}